@@ -0,0 +1,583 @@
+package blockvalidation
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/beacon"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/params"
+
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+)
+
+// errBlacklistedAddress is wrapped with the offending address and returned
+// whenever a submitted payload touches a sanctioned account, either as a
+// transaction sender, a call target, or a newly created contract.
+var errBlacklistedAddress = errors.New("sanctioned address")
+
+// BlockValidationConfig groups the optional settings for BlockValidationAPI.
+type BlockValidationConfig struct {
+	// BlacklistSourceFilePath is the path to a JSON file containing the
+	// sanctioned addresses to enforce. Leave empty to disable the check.
+	BlacklistSourceFilePath string
+
+	// ValidationForceLastTxPayment requires the final transaction of a
+	// submission to be a direct ETH transfer from the block's FeeRecipient
+	// to BidTrace.ProposerFeeRecipient for exactly BidTrace.Value wei,
+	// instead of trusting the builder's overall coinbase balance delta
+	// alone to prove the proposer was paid.
+	ValidationForceLastTxPayment bool
+
+	// AuthBearerToken, if set, must be presented as "Authorization: Bearer
+	// <token>" on every request to the RPC endpoint registered by Register.
+	AuthBearerToken string
+
+	// AuthAllowedIPs, if non-empty, restricts requests to the RPC endpoint
+	// registered by Register to the given set of remote IP addresses.
+	AuthAllowedIPs []string
+}
+
+// Blacklist is the set of addresses that ValidateBuilderSubmission* refuses
+// to see touched by a builder's payload.
+type Blacklist map[common.Address]struct{}
+
+// IsBlacklisted reports whether addr is a sanctioned address.
+func (b Blacklist) IsBlacklisted(addr common.Address) bool {
+	_, blocked := b[addr]
+	return blocked
+}
+
+// LoadBlacklist reads a JSON array of hex-encoded addresses from path and
+// returns the resulting Blacklist. An empty path yields an empty, disabled
+// Blacklist.
+func LoadBlacklist(path string) (Blacklist, error) {
+	if path == "" {
+		return Blacklist{}, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read blacklist file: %w", err)
+	}
+
+	var addresses []common.Address
+	if err := json.Unmarshal(raw, &addresses); err != nil {
+		return nil, fmt.Errorf("could not parse blacklist file: %w", err)
+	}
+
+	blacklist := make(Blacklist, len(addresses))
+	for _, addr := range addresses {
+		blacklist[addr] = struct{}{}
+	}
+	return blacklist, nil
+}
+
+// BlockValidationAPI exposes the `flashbots_validateBuilderSubmission*`
+// methods used by relays to verify a builder's block before accepting its
+// bid.
+type BlockValidationAPI struct {
+	eth *eth.Ethereum
+	cfg BlockValidationConfig
+
+	blacklistMu sync.RWMutex
+	blacklist   Blacklist
+}
+
+// NewBlockValidationAPI creates a BlockValidationAPI backed by eth, enforcing
+// blacklist and cfg on every submission. A nil or empty blacklist disables
+// the sanctioned-address check.
+func NewBlockValidationAPI(eth *eth.Ethereum, blacklist Blacklist, cfg BlockValidationConfig) *BlockValidationAPI {
+	return &BlockValidationAPI{eth: eth, blacklist: blacklist, cfg: cfg}
+}
+
+// ReloadBlacklist reloads the sanctioned-address list from path, atomically
+// swapping it in so in-flight validations keep using the previous list. It
+// is exposed over RPC so relay operators can update the list without
+// restarting the node.
+func (api *BlockValidationAPI) ReloadBlacklist(path string) error {
+	blacklist, err := LoadBlacklist(path)
+	if err != nil {
+		return err
+	}
+
+	api.blacklistMu.Lock()
+	api.blacklist = blacklist
+	api.blacklistMu.Unlock()
+	return nil
+}
+
+// ValidateBuilderSubmissionV1 validates a pre-Shanghai builder block
+// submission: it checks that the payload hashes to the claimed block hash,
+// that GasUsed matches, that no transaction touches a blacklisted address,
+// and that the coinbase was paid exactly BidTrace.Value.
+func (api *BlockValidationAPI) ValidateBuilderSubmissionV1(params *boostTypes.BuilderSubmitBlockRequest) error {
+	if params == nil || params.ExecutionPayload == nil || params.Message == nil {
+		return errors.New("missing builder submission fields")
+	}
+
+	block, err := beacon.ExecutableDataToBlock(*ExecutionPayloadToExecutableData(params.ExecutionPayload))
+	if err != nil {
+		return fmt.Errorf("could not convert execution payload to block: %w", err)
+	}
+
+	return api.validateBlock(block, params.Message, nil, api.cfg)
+}
+
+// ValidateBuilderSubmissionV2 validates a Shanghai builder block submission,
+// extending ValidateBuilderSubmissionV1 with EIP-4895 withdrawals: the
+// withdrawals root, the sequencing of withdrawal indices, and that every
+// withdrawal was applied as a plain balance credit.
+func (api *BlockValidationAPI) ValidateBuilderSubmissionV2(params *boostTypes.BuilderSubmitBlockRequestV2) error {
+	if params == nil || params.ExecutionPayload == nil || params.Message == nil {
+		return errors.New("missing builder submission fields")
+	}
+
+	block, err := beacon.ExecutableDataToBlockV2(*ExecutionPayloadV2ToExecutableData(params.ExecutionPayload))
+	if err != nil {
+		return fmt.Errorf("could not convert execution payload to block: %w", err)
+	}
+
+	return api.validateBlock(block, params.Message, nil, api.cfg)
+}
+
+// ValidateBuilderSubmissionV3 validates a Cancun builder block submission,
+// extending ValidateBuilderSubmissionV2 with EIP-4844 blob transactions:
+// every blob's KZG commitment and proof, the header's blob_gas_used and
+// excess_blob_gas, and that bundle is consistent with the transactions
+// actually included in the block.
+func (api *BlockValidationAPI) ValidateBuilderSubmissionV3(params *boostTypes.BuilderSubmitBlockRequestV3) error {
+	if params == nil || params.ExecutionPayload == nil || params.Message == nil {
+		return errors.New("missing builder submission fields")
+	}
+
+	block, err := beacon.ExecutableDataToBlockV3(*ExecutionPayloadV3ToExecutableData(params.ExecutionPayload))
+	if err != nil {
+		return fmt.Errorf("could not convert execution payload to block: %w", err)
+	}
+
+	return api.validateBlock(block, params.Message, params.BlobsBundle, api.cfg)
+}
+
+func (api *BlockValidationAPI) validateBlock(block *types.Block, message *boostTypes.BidTrace, bundle *boostTypes.BlobsBundle, cfg BlockValidationConfig) error {
+	if block.Hash() != common.Hash(message.BlockHash) {
+		return fmt.Errorf("blockhash mismatch, got %s, expected %s", block.Hash(), common.Hash(message.BlockHash))
+	}
+	if block.GasUsed() != message.GasUsed {
+		return fmt.Errorf("incorrect GasUsed %d, expected %d", message.GasUsed, block.GasUsed())
+	}
+
+	if err := api.checkBlacklist(block); err != nil {
+		return err
+	}
+
+	feeRecipient := block.Coinbase()
+
+	// In ValidationForceLastTxPayment mode the final transaction forwards
+	// the accrued profit to the proposer, so the profit itself has to be
+	// measured before that transaction runs, and checked structurally
+	// against the final transaction rather than the post-execution balance.
+	if cfg.ValidationForceLastTxPayment {
+		if err := api.validateProposerPaymentTx(block, message); err != nil {
+			return err
+		}
+		profit, err := api.coinbaseProfitExcludingLastTx(block, feeRecipient)
+		if err != nil {
+			return err
+		}
+
+		if err := api.eth.BlockChain().InsertBlockWithoutSetHead(block); err != nil {
+			return err
+		}
+		if err := api.validateProposerPaymentReceipt(block); err != nil {
+			return err
+		}
+		if block.Header().WithdrawalsHash != nil {
+			if err := api.validateWithdrawals(block); err != nil {
+				return err
+			}
+		}
+		if block.Header().ExcessBlobGas != nil {
+			if err := api.validateBlobGas(block, bundle); err != nil {
+				return err
+			}
+		}
+
+		if profit.Cmp(message.Value.BigInt()) != 0 {
+			return fmt.Errorf("inaccurate payment %s, expected %s", profit, message.Value.BigInt())
+		}
+		return nil
+	}
+
+	parentState, err := api.eth.BlockChain().StateAt(block.ParentHash())
+	if err != nil {
+		return fmt.Errorf("could not load parent state: %w", err)
+	}
+	balanceBefore := new(big.Int).Set(parentState.GetBalance(feeRecipient))
+
+	if err := api.eth.BlockChain().InsertBlockWithoutSetHead(block); err != nil {
+		return err
+	}
+
+	if block.Header().WithdrawalsHash != nil {
+		if err := api.validateWithdrawals(block); err != nil {
+			return err
+		}
+	}
+
+	if block.Header().ExcessBlobGas != nil {
+		if err := api.validateBlobGas(block, bundle); err != nil {
+			return err
+		}
+	}
+
+	postState, err := api.eth.BlockChain().StateAt(block.Root())
+	if err != nil {
+		return fmt.Errorf("could not load post-execution state: %w", err)
+	}
+	profit := new(big.Int).Sub(postState.GetBalance(feeRecipient), balanceBefore)
+
+	// Withdrawal credits are not part of the block's fee revenue; exclude
+	// any paid to the fee recipient before comparing against BidTrace.Value.
+	for _, w := range block.Withdrawals() {
+		if w.Address == feeRecipient {
+			profit.Sub(profit, gweiToWei(w.Amount))
+		}
+	}
+
+	// Blob-gas fees are burned, exactly like the EIP-1559 base fee, so they
+	// never reach feeRecipient's balance and profit needs no adjustment for
+	// them; validateBlobGas above only confirms blob_gas_used/excess_blob_gas
+	// were computed honestly.
+
+	if profit.Cmp(message.Value.BigInt()) != 0 {
+		return fmt.Errorf("inaccurate payment %s, expected %s", profit, message.Value.BigInt())
+	}
+	return nil
+}
+
+// validateWithdrawals checks that block's EIP-4895 withdrawals are well
+// formed: each withdrawal index continues sequentially from the parent
+// block's last withdrawal, and every withdrawal was applied to the
+// post-state as a plain balance credit in gwei with no gas cost and no
+// nonce bump. The withdrawals root itself needs no separate check here:
+// block was built from the same withdrawals list by ExecutableDataToBlockV2,
+// and validateBlock's earlier block.Hash() comparison against the bid
+// already catches any header/withdrawals inconsistency before this runs.
+func (api *BlockValidationAPI) validateWithdrawals(block *types.Block) error {
+	withdrawals := block.Withdrawals()
+
+	parent := api.eth.BlockChain().GetBlockByHash(block.ParentHash())
+	if parent == nil {
+		return fmt.Errorf("could not find parent block %s", block.ParentHash())
+	}
+	nextIndex := uint64(0)
+	if parentWithdrawals := parent.Withdrawals(); len(parentWithdrawals) > 0 {
+		nextIndex = parentWithdrawals[len(parentWithdrawals)-1].Index + 1
+	}
+	for i, w := range withdrawals {
+		if w.Index != nextIndex {
+			return fmt.Errorf("withdrawal %d has index %d, expected %d", i, w.Index, nextIndex)
+		}
+		nextIndex++
+	}
+
+	preState, err := api.replayTransactions(block, len(block.Transactions()))
+	if err != nil {
+		return err
+	}
+	postState, err := api.eth.BlockChain().StateAt(block.Root())
+	if err != nil {
+		return fmt.Errorf("could not load post-execution state: %w", err)
+	}
+
+	credited := make(map[common.Address]*big.Int, len(withdrawals))
+	for _, w := range withdrawals {
+		if total, ok := credited[w.Address]; ok {
+			total.Add(total, gweiToWei(w.Amount))
+		} else {
+			credited[w.Address] = gweiToWei(w.Amount)
+		}
+	}
+	for addr, amount := range credited {
+		if preState.GetNonce(addr) != postState.GetNonce(addr) {
+			return fmt.Errorf("withdrawal to %s incorrectly bumped the nonce", addr)
+		}
+		got := new(big.Int).Sub(postState.GetBalance(addr), preState.GetBalance(addr))
+		if got.Cmp(amount) != 0 {
+			return fmt.Errorf("withdrawal credit to %s is %s, expected %s", addr, got, amount)
+		}
+	}
+	return nil
+}
+
+// validateBlobGas checks block's EIP-4844 blob-carrying transactions against
+// bundle: every commitment must hash to the versioned hash the corresponding
+// transaction declared, every blob must KZG-verify against its commitment
+// and proof, and the header's blob_gas_used and excess_blob_gas must match
+// what the transactions and parent header imply.
+func (api *BlockValidationAPI) validateBlobGas(block *types.Block, bundle *boostTypes.BlobsBundle) error {
+	if bundle == nil {
+		return errors.New("missing blobs bundle")
+	}
+
+	var hashes []common.Hash
+	for _, tx := range block.Transactions() {
+		hashes = append(hashes, tx.BlobHashes()...)
+	}
+	if len(hashes) != len(bundle.Commitments) || len(hashes) != len(bundle.Proofs) || len(hashes) != len(bundle.Blobs) {
+		return fmt.Errorf("blobs bundle length mismatch: %d blob hashes, %d commitments, %d proofs, %d blobs",
+			len(hashes), len(bundle.Commitments), len(bundle.Proofs), len(bundle.Blobs))
+	}
+
+	for i, hash := range hashes {
+		commitment := kzg4844.Commitment(bundle.Commitments[i])
+		if computed := kzg4844.CalcBlobHashV1(sha256.New(), &commitment); computed != hash {
+			return fmt.Errorf("blob %d commitment does not match versioned hash, got %s, expected %s", i, computed, hash)
+		}
+		if err := kzg4844.VerifyBlobProof(kzg4844.Blob(bundle.Blobs[i]), commitment, kzg4844.Proof(bundle.Proofs[i])); err != nil {
+			return fmt.Errorf("invalid KZG proof for blob %d: %w", i, err)
+		}
+	}
+
+	header := block.Header()
+	blobGasUsed := uint64(len(hashes)) * params.BlobTxBlobGasPerBlob
+	if header.BlobGasUsed == nil || *header.BlobGasUsed != blobGasUsed {
+		return fmt.Errorf("incorrect BlobGasUsed %d, expected %d", valueOrZero(header.BlobGasUsed), blobGasUsed)
+	}
+
+	parent := api.eth.BlockChain().GetHeaderByHash(block.ParentHash())
+	if parent == nil {
+		return fmt.Errorf("could not find parent header %s", block.ParentHash())
+	}
+	expectedExcess := eip4844.CalcExcessBlobGas(valueOrZero(parent.ExcessBlobGas), valueOrZero(parent.BlobGasUsed))
+	if header.ExcessBlobGas == nil || *header.ExcessBlobGas != expectedExcess {
+		return fmt.Errorf("incorrect ExcessBlobGas %d, expected %d", valueOrZero(header.ExcessBlobGas), expectedExcess)
+	}
+	return nil
+}
+
+func gweiToWei(amount uint64) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(amount), big.NewInt(params.GWei))
+}
+
+// valueOrZero returns *v, or 0 if v is nil.
+func valueOrZero(v *uint64) uint64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// validateProposerPaymentTx checks the structural shape of block's final
+// transaction against the required proposer payment: sent by the block's
+// FeeRecipient, to BidTrace.ProposerFeeRecipient, for exactly
+// BidTrace.Value wei, with no calldata.
+func (api *BlockValidationAPI) validateProposerPaymentTx(block *types.Block, message *boostTypes.BidTrace) error {
+	txs := block.Transactions()
+	if len(txs) == 0 {
+		return errors.New("last tx is not proposer payment: block contains no transactions")
+	}
+	lastTx := txs[len(txs)-1]
+
+	signer := types.LatestSignerForChainID(api.eth.BlockChain().Config().ChainID)
+	from, err := types.Sender(signer, lastTx)
+	if err != nil {
+		return fmt.Errorf("could not recover proposer payment sender: %w", err)
+	}
+
+	proposer := common.Address(message.ProposerFeeRecipient)
+	switch {
+	case from != block.Coinbase():
+		return fmt.Errorf("last tx is not proposer payment: sent from %s, expected builder %s", from, block.Coinbase())
+	case lastTx.To() == nil || *lastTx.To() != proposer:
+		return fmt.Errorf("last tx is not proposer payment: does not pay proposer %s", proposer)
+	case len(lastTx.Data()) != 0:
+		return errors.New("last tx is not proposer payment: must not carry calldata")
+	case lastTx.Value().Cmp(message.Value.BigInt()) != 0:
+		return fmt.Errorf("last tx is not proposer payment: transferred %s, expected %s", lastTx.Value(), message.Value.BigInt())
+	}
+	return nil
+}
+
+// validateProposerPaymentReceipt checks that the already-inserted block's
+// final transaction succeeded and emitted no logs. Other state changes it
+// may cause, such as storage writes by code at the recipient address, are
+// not checked.
+func (api *BlockValidationAPI) validateProposerPaymentReceipt(block *types.Block) error {
+	receipts := api.eth.BlockChain().GetReceiptsByHash(block.Hash())
+	if len(receipts) != len(block.Transactions()) {
+		return errors.New("last tx is not proposer payment: missing receipts")
+	}
+
+	lastReceipt := receipts[len(receipts)-1]
+	if lastReceipt.Status != types.ReceiptStatusSuccessful {
+		return errors.New("last tx is not proposer payment: transaction reverted")
+	}
+	if len(lastReceipt.Logs) != 0 {
+		return errors.New("last tx is not proposer payment: produced unexpected logs")
+	}
+	return nil
+}
+
+// coinbaseProfitExcludingLastTx replays block's transactions, except the
+// final one, against the parent state and returns the resulting change in
+// feeRecipient's balance. ValidationForceLastTxPayment mode uses this in
+// place of the full post-state delta, since the final transaction forwards
+// the accrued profit onward rather than contributing to it.
+func (api *BlockValidationAPI) coinbaseProfitExcludingLastTx(block *types.Block, feeRecipient common.Address) (*big.Int, error) {
+	parentState, err := api.eth.BlockChain().StateAt(block.ParentHash())
+	if err != nil {
+		return nil, fmt.Errorf("could not load parent state: %w", err)
+	}
+	balanceBefore := new(big.Int).Set(parentState.GetBalance(feeRecipient))
+
+	afterTxs, err := api.replayTransactions(block, len(block.Transactions())-1)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Sub(afterTxs.GetBalance(feeRecipient), balanceBefore), nil
+}
+
+// replayTransactions executes block's first n transactions against its
+// parent state and returns the resulting StateDB, without touching the
+// chain. It is used to recover intermediate states - e.g. "before the
+// payment tx" or "before withdrawals are applied" - that InsertBlock alone
+// does not expose.
+func (api *BlockValidationAPI) replayTransactions(block *types.Block, n int) (*state.StateDB, error) {
+	statedb, err := api.eth.BlockChain().StateAt(block.ParentHash())
+	if err != nil {
+		return nil, fmt.Errorf("could not load parent state: %w", err)
+	}
+
+	chainConfig := api.eth.BlockChain().Config()
+	header := block.Header()
+	signer := types.MakeSigner(chainConfig, header.Number, header.Time)
+	blockContext := core.NewEVMBlockContext(header, api.eth.BlockChain(), &header.Coinbase)
+	vmenv := vm.NewEVM(blockContext, vm.TxContext{}, statedb, chainConfig, vm.Config{})
+	gasPool := new(core.GasPool).AddGas(header.GasLimit)
+
+	for i, tx := range block.Transactions()[:n] {
+		msg, err := tx.AsMessage(signer, header.BaseFee)
+		if err != nil {
+			return nil, fmt.Errorf("could not build message for tx %d: %w", i, err)
+		}
+		statedb.SetTxContext(tx.Hash(), i)
+		vmenv.TxContext = core.NewEVMTxContext(msg)
+		if _, err := core.ApplyMessage(vmenv, msg, gasPool); err != nil {
+			return nil, fmt.Errorf("could not apply tx %d: %w", i, err)
+		}
+	}
+	return statedb, nil
+}
+
+// checkBlacklist rejects block if any transaction's sender, call target, or
+// created-contract address is sanctioned.
+func (api *BlockValidationAPI) checkBlacklist(block *types.Block) error {
+	api.blacklistMu.RLock()
+	blacklist := api.blacklist
+	api.blacklistMu.RUnlock()
+
+	if len(blacklist) == 0 {
+		return nil
+	}
+
+	signer := types.LatestSignerForChainID(api.eth.BlockChain().Config().ChainID)
+	for _, tx := range block.Transactions() {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return fmt.Errorf("could not recover transaction sender: %w", err)
+		}
+		if blacklist.IsBlacklisted(from) {
+			return fmt.Errorf("%w: %s", errBlacklistedAddress, from)
+		}
+
+		if to := tx.To(); to != nil {
+			if blacklist.IsBlacklisted(*to) {
+				return fmt.Errorf("%w: %s", errBlacklistedAddress, *to)
+			}
+			continue
+		}
+
+		contractAddr := crypto.CreateAddress(from, tx.Nonce())
+		if blacklist.IsBlacklisted(contractAddr) {
+			return fmt.Errorf("%w: %s", errBlacklistedAddress, contractAddr)
+		}
+	}
+	return nil
+}
+
+// ExecutionPayloadToExecutableData converts a boost-utils execution payload,
+// as submitted by a builder, into the beacon.ExecutableDataV1 shape expected
+// by the rest of go-ethereum.
+func ExecutionPayloadToExecutableData(payload *boostTypes.ExecutionPayload) *beacon.ExecutableDataV1 {
+	transactions := make([][]byte, len(payload.Transactions))
+	for i, tx := range payload.Transactions {
+		transactions[i] = tx
+	}
+
+	return &beacon.ExecutableDataV1{
+		ParentHash:    common.Hash(payload.ParentHash),
+		FeeRecipient:  common.Address(payload.FeeRecipient),
+		StateRoot:     common.Hash(payload.StateRoot),
+		ReceiptsRoot:  common.Hash(payload.ReceiptsRoot),
+		LogsBloom:     payload.LogsBloom[:],
+		Random:        common.Hash(payload.Random),
+		Number:        payload.BlockNumber,
+		GasLimit:      payload.GasLimit,
+		GasUsed:       payload.GasUsed,
+		Timestamp:     payload.Timestamp,
+		ExtraData:     payload.ExtraData,
+		BaseFeePerGas: payload.BaseFeePerGas.BigInt(),
+		BlockHash:     common.Hash(payload.BlockHash),
+		Transactions:  transactions,
+	}
+}
+
+// ExecutionPayloadV2ToExecutableData converts a boost-utils V2 execution
+// payload, as submitted by a builder on a Shanghai block, into the
+// beacon.ExecutableDataV2 shape expected by the rest of go-ethereum.
+func ExecutionPayloadV2ToExecutableData(payload *boostTypes.ExecutionPayloadV2) *beacon.ExecutableDataV2 {
+	withdrawals := make([]*types.Withdrawal, len(payload.Withdrawals))
+	for i, w := range payload.Withdrawals {
+		withdrawals[i] = &types.Withdrawal{
+			Index:     w.Index,
+			Validator: w.Validator,
+			Address:   common.Address(w.Address),
+			Amount:    w.Amount,
+		}
+	}
+
+	return &beacon.ExecutableDataV2{
+		ExecutableDataV1: *ExecutionPayloadToExecutableData(&payload.ExecutionPayload),
+		Withdrawals:      withdrawals,
+	}
+}
+
+// ExecutionPayloadV3ToExecutableData converts a boost-utils V3 execution
+// payload, as submitted by a builder on a Cancun block, into the
+// beacon.ExecutableDataV3 shape expected by the rest of go-ethereum. The
+// accompanying blobs bundle is validated separately, by validateBlobGas.
+func ExecutionPayloadV3ToExecutableData(payload *boostTypes.ExecutionPayloadV3) *beacon.ExecutableDataV3 {
+	blobGasUsed := payload.BlobGasUsed
+	excessBlobGas := payload.ExcessBlobGas
+
+	return &beacon.ExecutableDataV3{
+		ExecutableDataV2: *ExecutionPayloadV2ToExecutableData(&payload.ExecutionPayloadV2),
+		BlobGasUsed:      &blobGasUsed,
+		ExcessBlobGas:    &excessBlobGas,
+	}
+}
@@ -13,12 +13,14 @@ import (
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/require"
 
 	boostTypes "github.com/flashbots/go-boost-utils/types"
@@ -42,7 +44,7 @@ func TestValidateBuilderSubmissionV1(t *testing.T) {
 	ethservice.Merger().ReachTTD()
 	defer n.Close()
 
-	api := NewBlockValidationAPI(ethservice)
+	api := NewBlockValidationAPI(ethservice, nil, BlockValidationConfig{})
 	parent := preMergeBlocks[len(preMergeBlocks)-1]
 
 	// This EVM code generates a log when the contract is created.
@@ -105,6 +107,336 @@ func TestValidateBuilderSubmissionV1(t *testing.T) {
 	require.ErrorContains(t, api.ValidateBuilderSubmissionV1(blockRequest), "could not apply tx 3", "insufficient funds for gas * price + value")
 }
 
+func TestValidateBuilderSubmissionV1Blacklist(t *testing.T) {
+	genesis, preMergeBlocks := generatePreMergeChain(20)
+	n, ethservice := startEthService(t, genesis, preMergeBlocks)
+	ethservice.Merger().ReachTTD()
+	defer n.Close()
+
+	blockedAddr := common.Address{0x16}
+	api := NewBlockValidationAPI(ethservice, Blacklist{blockedAddr: struct{}{}}, BlockValidationConfig{})
+	parent := preMergeBlocks[len(preMergeBlocks)-1]
+
+	statedb, _ := ethservice.BlockChain().StateAt(parent.Root())
+	nonce := statedb.GetNonce(testAddr)
+
+	tx1, _ := types.SignTx(types.NewTransaction(nonce, blockedAddr, big.NewInt(10), 21000, big.NewInt(2*params.InitialBaseFee), nil), types.LatestSigner(ethservice.BlockChain().Config()), testKey)
+	ethservice.TxPool().AddLocal(tx1)
+
+	execData, err := assembleBlock(api, parent.Hash(), &beacon.PayloadAttributesV1{
+		Timestamp: parent.Time() + 5,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, len(execData.Transactions), 1)
+
+	payload, err := ExecutableDataToExecutionPayload(execData)
+	require.NoError(t, err)
+
+	blockRequest := &boostTypes.BuilderSubmitBlockRequest{
+		Signature: boostTypes.Signature{},
+		Message: &boostTypes.BidTrace{
+			ParentHash: boostTypes.Hash(execData.ParentHash),
+			BlockHash:  boostTypes.Hash(execData.BlockHash),
+			GasLimit:   execData.GasLimit,
+			GasUsed:    execData.GasUsed,
+		},
+		ExecutionPayload: payload,
+	}
+	require.ErrorContains(t, api.ValidateBuilderSubmissionV1(blockRequest), "sanctioned address")
+}
+
+func TestValidateBuilderSubmissionV1ForceLastTxPaymentMissing(t *testing.T) {
+	genesis, preMergeBlocks := generatePreMergeChain(20)
+	n, ethservice := startEthService(t, genesis, preMergeBlocks)
+	ethservice.Merger().ReachTTD()
+	defer n.Close()
+
+	proposerAddr := common.Address{0x42}
+	api := NewBlockValidationAPI(ethservice, nil, BlockValidationConfig{ValidationForceLastTxPayment: true})
+	parent := preMergeBlocks[len(preMergeBlocks)-1]
+
+	statedb, _ := ethservice.BlockChain().StateAt(parent.Root())
+	nonce := statedb.GetNonce(testAddr)
+
+	// A plain transfer that does not pay the proposer.
+	tx1, _ := types.SignTx(types.NewTransaction(nonce, common.Address{0x16}, big.NewInt(10), 21000, big.NewInt(2*params.InitialBaseFee), nil), types.LatestSigner(ethservice.BlockChain().Config()), testKey)
+	ethservice.TxPool().AddLocal(tx1)
+
+	execData, err := assembleBlock(api, parent.Hash(), &beacon.PayloadAttributesV1{
+		Timestamp: parent.Time() + 5,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, len(execData.Transactions), 1)
+
+	payload, err := ExecutableDataToExecutionPayload(execData)
+	require.NoError(t, err)
+
+	blockRequest := &boostTypes.BuilderSubmitBlockRequest{
+		Signature: boostTypes.Signature{},
+		Message: &boostTypes.BidTrace{
+			ParentHash:           boostTypes.Hash(execData.ParentHash),
+			BlockHash:            boostTypes.Hash(execData.BlockHash),
+			GasLimit:             execData.GasLimit,
+			GasUsed:              execData.GasUsed,
+			ProposerFeeRecipient: boostTypes.Address(proposerAddr),
+		},
+		ExecutionPayload: payload,
+	}
+	require.ErrorContains(t, api.ValidateBuilderSubmissionV1(blockRequest), "last tx is not proposer payment")
+}
+
+func TestValidateBuilderSubmissionV1ForceLastTxPaymentValid(t *testing.T) {
+	genesis, preMergeBlocks := generatePreMergeChain(20)
+	n, ethservice := startEthService(t, genesis, preMergeBlocks)
+	ethservice.Merger().ReachTTD()
+	defer n.Close()
+
+	proposerAddr := common.Address{0x42}
+	api := NewBlockValidationAPI(ethservice, nil, BlockValidationConfig{ValidationForceLastTxPayment: true})
+	parent := preMergeBlocks[len(preMergeBlocks)-1]
+
+	statedb, _ := ethservice.BlockChain().StateAt(parent.Root())
+	nonce := statedb.GetNonce(testAddr)
+	signer := types.LatestSigner(ethservice.BlockChain().Config())
+
+	// tx1 is a plain transfer that earns the builder (testAddr, the
+	// etherbase) a priority-fee tip.
+	tx1, _ := types.SignTx(types.NewTransaction(nonce, common.Address{0x16}, big.NewInt(10), 21000, big.NewInt(2*params.InitialBaseFee), nil), signer, testKey)
+	ethservice.TxPool().AddLocal(tx1)
+
+	execData, err := assembleBlock(api, parent.Hash(), &beacon.PayloadAttributesV1{
+		Timestamp: parent.Time() + 5,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, len(execData.Transactions), 1)
+
+	// Profit tx1 generated for the builder: the gas it used times its
+	// priority fee over the block's base fee.
+	tip := new(big.Int).Sub(big.NewInt(2*params.InitialBaseFee), execData.BaseFeePerGas)
+	profit := new(big.Int).Mul(big.NewInt(21000), tip)
+
+	payment, _ := types.SignTx(types.NewTransaction(nonce+1, proposerAddr, profit, 21000, big.NewInt(2*params.InitialBaseFee), nil), signer, testKey)
+	ethservice.TxPool().AddLocal(payment)
+
+	execData, err = assembleBlock(api, parent.Hash(), &beacon.PayloadAttributesV1{
+		Timestamp: parent.Time() + 5,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, len(execData.Transactions), 2)
+
+	payload, err := ExecutableDataToExecutionPayload(execData)
+	require.NoError(t, err)
+
+	var value boostTypes.U256Str
+	require.NoError(t, value.FromBig(profit))
+
+	blockRequest := &boostTypes.BuilderSubmitBlockRequest{
+		Signature: boostTypes.Signature{},
+		Message: &boostTypes.BidTrace{
+			ParentHash:           boostTypes.Hash(execData.ParentHash),
+			BlockHash:            boostTypes.Hash(execData.BlockHash),
+			GasLimit:             execData.GasLimit,
+			GasUsed:              execData.GasUsed,
+			ProposerFeeRecipient: boostTypes.Address(proposerAddr),
+			Value:                value,
+		},
+		ExecutionPayload: payload,
+	}
+	require.NoError(t, api.ValidateBuilderSubmissionV1(blockRequest))
+}
+
+func TestValidateBuilderSubmissionV2(t *testing.T) {
+	genesis, preMergeBlocks := generateShanghaiPreMergeChain(20)
+	n, ethservice := startEthService(t, genesis, preMergeBlocks)
+	ethservice.Merger().ReachTTD()
+	defer n.Close()
+
+	api := NewBlockValidationAPI(ethservice, nil, BlockValidationConfig{})
+	parent := preMergeBlocks[len(preMergeBlocks)-1]
+
+	withdrawals := []*types.Withdrawal{
+		{Index: 0, Validator: 1, Address: common.Address{0x23}, Amount: 1000},
+		{Index: 1, Validator: 2, Address: common.Address{0x24}, Amount: 2000},
+	}
+
+	execData, err := assembleBlockV2(api, parent.Hash(), &beacon.PayloadAttributesV2{
+		PayloadAttributesV1: beacon.PayloadAttributesV1{Timestamp: parent.Time() + 5},
+		Withdrawals:         withdrawals,
+	})
+	require.NoError(t, err)
+
+	payload, err := ExecutableDataToExecutionPayloadV2(execData)
+	require.NoError(t, err)
+
+	blockRequest := &boostTypes.BuilderSubmitBlockRequestV2{
+		Signature: boostTypes.Signature{},
+		Message: &boostTypes.BidTrace{
+			ParentHash: boostTypes.Hash(execData.ParentHash),
+			BlockHash:  boostTypes.Hash(execData.BlockHash),
+			GasLimit:   execData.GasLimit,
+			GasUsed:    execData.GasUsed,
+		},
+		ExecutionPayload: payload,
+	}
+	require.NoError(t, api.ValidateBuilderSubmissionV2(blockRequest))
+
+	// Drop a withdrawal without updating the claimed block hash: the
+	// reconstructed block's hash, which bakes in the withdrawals list, no
+	// longer matches what was bid, so validateBlock's blockhash check
+	// rejects it before validateWithdrawals ever runs.
+	badPayload, err := ExecutableDataToExecutionPayloadV2(execData)
+	require.NoError(t, err)
+	badPayload.Withdrawals = badPayload.Withdrawals[:1]
+
+	blockRequest.ExecutionPayload = badPayload
+	require.ErrorContains(t, api.ValidateBuilderSubmissionV2(blockRequest), "blockhash mismatch")
+}
+
+// TestValidateBuilderSubmissionV2ForceLastTxPayment exercises
+// ValidationForceLastTxPayment together with withdrawals, which take a
+// different code path than the ordinary profit check in validateBlock: it
+// must still reject a withdrawals set that doesn't match the header, even
+// though the payment itself validates fine.
+func TestValidateBuilderSubmissionV2ForceLastTxPayment(t *testing.T) {
+	genesis, preMergeBlocks := generateShanghaiPreMergeChain(20)
+	n, ethservice := startEthService(t, genesis, preMergeBlocks)
+	ethservice.Merger().ReachTTD()
+	defer n.Close()
+
+	proposerAddr := common.Address{0x42}
+	api := NewBlockValidationAPI(ethservice, nil, BlockValidationConfig{ValidationForceLastTxPayment: true})
+	parent := preMergeBlocks[len(preMergeBlocks)-1]
+
+	withdrawals := []*types.Withdrawal{
+		{Index: 0, Validator: 1, Address: common.Address{0x23}, Amount: 1000},
+	}
+
+	statedb, _ := ethservice.BlockChain().StateAt(parent.Root())
+	nonce := statedb.GetNonce(testAddr)
+	signer := types.LatestSigner(ethservice.BlockChain().Config())
+
+	// tx1 is a plain transfer that earns the builder (testAddr, the
+	// etherbase) a priority-fee tip.
+	tx1, _ := types.SignTx(types.NewTransaction(nonce, common.Address{0x16}, big.NewInt(10), 21000, big.NewInt(2*params.InitialBaseFee), nil), signer, testKey)
+	ethservice.TxPool().AddLocal(tx1)
+
+	execData, err := assembleBlockV2(api, parent.Hash(), &beacon.PayloadAttributesV2{
+		PayloadAttributesV1: beacon.PayloadAttributesV1{Timestamp: parent.Time() + 5},
+		Withdrawals:         withdrawals,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, len(execData.Transactions), 1)
+
+	// Profit tx1 generated for the builder: the gas it used times its
+	// priority fee over the block's base fee.
+	tip := new(big.Int).Sub(big.NewInt(2*params.InitialBaseFee), execData.BaseFeePerGas)
+	profit := new(big.Int).Mul(big.NewInt(21000), tip)
+
+	payment, _ := types.SignTx(types.NewTransaction(nonce+1, proposerAddr, profit, 21000, big.NewInt(2*params.InitialBaseFee), nil), signer, testKey)
+	ethservice.TxPool().AddLocal(payment)
+
+	execData, err = assembleBlockV2(api, parent.Hash(), &beacon.PayloadAttributesV2{
+		PayloadAttributesV1: beacon.PayloadAttributesV1{Timestamp: parent.Time() + 5},
+		Withdrawals:         withdrawals,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, len(execData.Transactions), 2)
+
+	payload, err := ExecutableDataToExecutionPayloadV2(execData)
+	require.NoError(t, err)
+
+	var value boostTypes.U256Str
+	require.NoError(t, value.FromBig(profit))
+
+	blockRequest := &boostTypes.BuilderSubmitBlockRequestV2{
+		Signature: boostTypes.Signature{},
+		Message: &boostTypes.BidTrace{
+			ParentHash:           boostTypes.Hash(execData.ParentHash),
+			BlockHash:            boostTypes.Hash(execData.BlockHash),
+			GasLimit:             execData.GasLimit,
+			GasUsed:              execData.GasUsed,
+			ProposerFeeRecipient: boostTypes.Address(proposerAddr),
+			Value:                value,
+		},
+		ExecutionPayload: payload,
+	}
+	require.NoError(t, api.ValidateBuilderSubmissionV2(blockRequest))
+
+	// Drop a withdrawal without updating the claimed block hash: the
+	// payment itself is still valid, but the reconstructed block's hash,
+	// which bakes in the withdrawals list, no longer matches the claimed
+	// one, so the block-hash check rejects it before the block is inserted.
+	badPayload, err := ExecutableDataToExecutionPayloadV2(execData)
+	require.NoError(t, err)
+	badPayload.Withdrawals = badPayload.Withdrawals[:0]
+
+	blockRequest.ExecutionPayload = badPayload
+	require.ErrorContains(t, api.ValidateBuilderSubmissionV2(blockRequest), "blockhash mismatch")
+}
+
+func TestValidateBuilderSubmissionV3(t *testing.T) {
+	genesis, preMergeBlocks := generateCancunPreMergeChain(20)
+	n, ethservice := startEthService(t, genesis, preMergeBlocks)
+	ethservice.Merger().ReachTTD()
+	defer n.Close()
+
+	api := NewBlockValidationAPI(ethservice, nil, BlockValidationConfig{})
+	parent := preMergeBlocks[len(preMergeBlocks)-1]
+
+	statedb, _ := ethservice.BlockChain().StateAt(parent.Root())
+	nonce := statedb.GetNonce(testAddr)
+	signer := types.LatestSigner(ethservice.BlockChain().Config())
+
+	blobTx, sidecar := newSignedBlobTx(t, signer, nonce)
+	ethservice.TxPool().AddLocal(blobTx)
+
+	execData, err := assembleBlockV3(api, parent.Hash(), &beacon.PayloadAttributesV3{
+		PayloadAttributesV2: beacon.PayloadAttributesV2{
+			PayloadAttributesV1: beacon.PayloadAttributesV1{Timestamp: parent.Time() + 5},
+		},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, len(execData.Transactions))
+
+	payload, err := ExecutableDataToExecutionPayloadV3(execData)
+	require.NoError(t, err)
+
+	// The block's fee recipient earns the blob tx's priority tip: the gas it
+	// used times its fee cap over the block's base fee.
+	tip := new(big.Int).Sub(big.NewInt(2*params.InitialBaseFee), execData.BaseFeePerGas)
+	var value boostTypes.U256Str
+	require.NoError(t, value.FromBig(new(big.Int).Mul(big.NewInt(21000), tip)))
+
+	bundle := &boostTypes.BlobsBundle{
+		Commitments: []boostTypes.KZGCommitment{boostTypes.KZGCommitment(sidecar.Commitments[0])},
+		Proofs:      []boostTypes.KZGProof{boostTypes.KZGProof(sidecar.Proofs[0])},
+		Blobs:       []boostTypes.Blob{boostTypes.Blob(sidecar.Blobs[0])},
+	}
+
+	blockRequest := &boostTypes.BuilderSubmitBlockRequestV3{
+		Signature: boostTypes.Signature{},
+		Message: &boostTypes.BidTrace{
+			ParentHash: boostTypes.Hash(execData.ParentHash),
+			BlockHash:  boostTypes.Hash(execData.BlockHash),
+			GasLimit:   execData.GasLimit,
+			GasUsed:    execData.GasUsed,
+			Value:      value,
+		},
+		ExecutionPayload: payload,
+		BlobsBundle:      bundle,
+	}
+	require.NoError(t, api.ValidateBuilderSubmissionV3(blockRequest))
+
+	// Corrupt the proof without touching the commitment: the blob no longer
+	// KZG-verifies, even though its versioned hash still matches.
+	badBundle := *bundle
+	badProof := badBundle.Proofs[0]
+	badProof[0] ^= 0xff
+	badBundle.Proofs = []boostTypes.KZGProof{badProof}
+	blockRequest.BlobsBundle = &badBundle
+	require.ErrorContains(t, api.ValidateBuilderSubmissionV3(blockRequest), "invalid KZG proof")
+}
+
 func generatePreMergeChain(n int) (*core.Genesis, []*types.Block) {
 	db := rawdb.NewMemoryDatabase()
 	config := params.AllEthashProtocolChanges
@@ -205,3 +537,114 @@ func ExecutableDataToExecutionPayload(data *beacon.ExecutableDataV1) (*boostType
 		Transactions:  transactionData,
 	}, nil
 }
+
+// generateShanghaiPreMergeChain is generatePreMergeChain with Shanghai
+// already active from genesis, so assembled blocks carry withdrawals.
+func generateShanghaiPreMergeChain(n int) (*core.Genesis, []*types.Block) {
+	genesis, blocks := generatePreMergeChain(n)
+
+	// Copy before mutating: genesis.Config aliases the shared
+	// params.AllEthashProtocolChanges, and other tests rely on it staying
+	// pre-Shanghai.
+	shanghaiConfig := *genesis.Config
+	shanghaiTime := uint64(0)
+	shanghaiConfig.ShanghaiTime = &shanghaiTime
+	genesis.Config = &shanghaiConfig
+	return genesis, blocks
+}
+
+func assembleBlockV2(api *BlockValidationAPI, parentHash common.Hash, params *beacon.PayloadAttributesV2) (*beacon.ExecutableDataV2, error) {
+	block, err := api.eth.Miner().GetSealingBlockSync(parentHash, params.Timestamp, params.SuggestedFeeRecipient, params.Random, params.Withdrawals, false)
+	if err != nil {
+		return nil, err
+	}
+	return beacon.BlockToExecutableDataV2(block), nil
+}
+
+func ExecutableDataToExecutionPayloadV2(data *beacon.ExecutableDataV2) (*boostTypes.ExecutionPayloadV2, error) {
+	payload, err := ExecutableDataToExecutionPayload(&data.ExecutableDataV1)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawals := make([]*boostTypes.Withdrawal, len(data.Withdrawals))
+	for i, w := range data.Withdrawals {
+		withdrawals[i] = &boostTypes.Withdrawal{
+			Index:     w.Index,
+			Validator: w.Validator,
+			Address:   boostTypes.Address(w.Address),
+			Amount:    w.Amount,
+		}
+	}
+
+	return &boostTypes.ExecutionPayloadV2{
+		ExecutionPayload: *payload,
+		Withdrawals:      withdrawals,
+	}, nil
+}
+
+// generateCancunPreMergeChain is generateShanghaiPreMergeChain with Cancun
+// already active from genesis, so assembled blocks can carry blob txs.
+func generateCancunPreMergeChain(n int) (*core.Genesis, []*types.Block) {
+	genesis, blocks := generateShanghaiPreMergeChain(n)
+
+	cancunConfig := *genesis.Config
+	cancunTime := uint64(0)
+	cancunConfig.CancunTime = &cancunTime
+	genesis.Config = &cancunConfig
+	return genesis, blocks
+}
+
+func assembleBlockV3(api *BlockValidationAPI, parentHash common.Hash, params *beacon.PayloadAttributesV3) (*beacon.ExecutableDataV3, error) {
+	block, err := api.eth.Miner().GetSealingBlockSync(parentHash, params.Timestamp, params.SuggestedFeeRecipient, params.Random, params.Withdrawals, false)
+	if err != nil {
+		return nil, err
+	}
+	return beacon.BlockToExecutableDataV3(block), nil
+}
+
+func ExecutableDataToExecutionPayloadV3(data *beacon.ExecutableDataV3) (*boostTypes.ExecutionPayloadV3, error) {
+	payload, err := ExecutableDataToExecutionPayloadV2(&data.ExecutableDataV2)
+	if err != nil {
+		return nil, err
+	}
+
+	return &boostTypes.ExecutionPayloadV3{
+		ExecutionPayloadV2: *payload,
+		BlobGasUsed:        *data.BlobGasUsed,
+		ExcessBlobGas:      *data.ExcessBlobGas,
+	}, nil
+}
+
+// newSignedBlobTx builds a single-blob type-3 transaction signed by testKey,
+// along with the sidecar carrying its blob, commitment and proof.
+func newSignedBlobTx(t *testing.T, signer types.Signer, nonce uint64) (*types.Transaction, *types.BlobTxSidecar) {
+	t.Helper()
+
+	var blob kzg4844.Blob
+	commitment, err := kzg4844.BlobToCommitment(blob)
+	require.NoError(t, err)
+	proof, err := kzg4844.ComputeBlobKZGProof(blob, commitment)
+	require.NoError(t, err)
+
+	sidecar := &types.BlobTxSidecar{
+		Blobs:       []kzg4844.Blob{blob},
+		Commitments: []kzg4844.Commitment{commitment},
+		Proofs:      []kzg4844.Proof{proof},
+	}
+
+	to := common.Address{0x16}
+	tx, err := types.SignTx(types.NewTx(&types.BlobTx{
+		ChainID:    uint256.MustFromBig(params.AllEthashProtocolChanges.ChainID),
+		Nonce:      nonce,
+		GasTipCap:  uint256.NewInt(2 * params.InitialBaseFee),
+		GasFeeCap:  uint256.NewInt(2 * params.InitialBaseFee),
+		Gas:        21000,
+		To:         to,
+		BlobFeeCap: uint256.NewInt(1),
+		BlobHashes: sidecar.BlobHashes(),
+		Sidecar:    sidecar,
+	}), signer, testKey)
+	require.NoError(t, err)
+	return tx, sidecar
+}
@@ -0,0 +1,75 @@
+package blockvalidation
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Register builds a BlockValidationAPI backed by ethservice and cfg, and
+// exposes its flashbots_validateBuilderSubmissionV1/V2/V3 methods under the
+// "flashbots" namespace on stack's HTTP server: over HTTP at /flashbots and
+// over WebSocket at /flashbots/ws. Requests are
+// gated by cfg.AuthBearerToken and cfg.AuthAllowedIPs, since validating a
+// submission replays its block and is too expensive to leave open to the
+// public internet.
+func Register(stack *node.Node, ethservice *eth.Ethereum, cfg BlockValidationConfig) error {
+	blacklist, err := LoadBlacklist(cfg.BlacklistSourceFilePath)
+	if err != nil {
+		return err
+	}
+	api := NewBlockValidationAPI(ethservice, blacklist, cfg)
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("flashbots", api); err != nil {
+		return fmt.Errorf("could not register flashbots API: %w", err)
+	}
+
+	stack.RegisterHandler("flashbots RPC (HTTP)", "/flashbots", authMiddleware(cfg, server))
+	stack.RegisterHandler("flashbots RPC (WS)", "/flashbots/ws", authMiddleware(cfg, server.WebsocketHandler(nil)))
+	return nil
+}
+
+// authMiddleware wraps next with cfg's bearer-token and IP-allowlist checks,
+// rejecting a request before it reaches the RPC server if either is
+// configured and not satisfied.
+func authMiddleware(cfg BlockValidationConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.AuthBearerToken != "" {
+			const prefix = "Bearer "
+			header := r.Header.Get("Authorization")
+			if !strings.HasPrefix(header, prefix) || subtle.ConstantTimeCompare([]byte(header[len(prefix):]), []byte(cfg.AuthBearerToken)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		if len(cfg.AuthAllowedIPs) != 0 {
+			host, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				host = r.RemoteAddr
+			}
+			if !isAllowedIP(host, cfg.AuthAllowedIPs) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isAllowedIP(host string, allowed []string) bool {
+	for _, ip := range allowed {
+		if ip == host {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+package blockvalidation
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/beacon"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+)
+
+// newEthServiceHTTP creates a node with its HTTP server enabled and an eth
+// service on it, for tests that need to reach BlockValidationAPI over the
+// wire. Unlike
+// startEthService, it leaves the node unstarted so callers can Register
+// against it first: node.Node.RegisterHandler panics once the node has left
+// its initializing state.
+func newEthServiceHTTP(t *testing.T, genesis *core.Genesis) (*node.Node, *eth.Ethereum) {
+	t.Helper()
+
+	n, err := node.New(&node.Config{
+		P2P: p2p.Config{
+			ListenAddr:  "0.0.0.0:0",
+			NoDiscovery: true,
+			MaxPeers:    25,
+		},
+		HTTPHost: "127.0.0.1",
+		HTTPPort: 0,
+	})
+	if err != nil {
+		t.Fatal("can't create node:", err)
+	}
+
+	ethcfg := &ethconfig.Config{Genesis: genesis, Ethash: ethash.Config{PowMode: ethash.ModeFake}, SyncMode: downloader.SnapSync, TrieTimeout: time.Minute, TrieDirtyCache: 256, TrieCleanCache: 256}
+	ethservice, err := eth.New(n, ethcfg)
+	if err != nil {
+		t.Fatal("can't create eth service:", err)
+	}
+	return n, ethservice
+}
+
+// startAndImportChain starts n, which must have already had any
+// node.RegisterHandler callers (such as Register) run against it, imports
+// blocks, and brings ethservice up to a synced, mining-ready state.
+func startAndImportChain(t *testing.T, n *node.Node, ethservice *eth.Ethereum, blocks []*types.Block) {
+	t.Helper()
+
+	if err := n.Start(); err != nil {
+		t.Fatal("can't start node:", err)
+	}
+	if _, err := ethservice.BlockChain().InsertChain(blocks); err != nil {
+		n.Close()
+		t.Fatal("can't import test blocks:", err)
+	}
+	time.Sleep(500 * time.Millisecond) // give txpool enough time to consume head event
+
+	ethservice.SetEtherbase(testAddr)
+	ethservice.SetSynced()
+}
+
+func TestRegisterValidateBuilderSubmissionV1OverRPC(t *testing.T) {
+	genesis, preMergeBlocks := generatePreMergeChain(20)
+	n, ethservice := newEthServiceHTTP(t, genesis)
+	defer n.Close()
+
+	const token = "test-token"
+	require.NoError(t, Register(n, ethservice, BlockValidationConfig{AuthBearerToken: token}))
+
+	startAndImportChain(t, n, ethservice, preMergeBlocks)
+	ethservice.Merger().ReachTTD()
+
+	parent := preMergeBlocks[len(preMergeBlocks)-1]
+	api := NewBlockValidationAPI(ethservice, nil, BlockValidationConfig{})
+	execData, err := assembleBlock(api, parent.Hash(), &beacon.PayloadAttributesV1{
+		Timestamp: parent.Time() + 5,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 0, len(execData.Transactions))
+
+	payload, err := ExecutableDataToExecutionPayload(execData)
+	require.NoError(t, err)
+
+	blockRequest := &boostTypes.BuilderSubmitBlockRequest{
+		Signature: boostTypes.Signature{},
+		Message: &boostTypes.BidTrace{
+			ParentHash: boostTypes.Hash(execData.ParentHash),
+			BlockHash:  boostTypes.Hash(execData.BlockHash),
+			GasLimit:   execData.GasLimit,
+			GasUsed:    execData.GasUsed,
+			Value:      boostTypes.IntToU256(0),
+		},
+		ExecutionPayload: payload,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	endpoint := n.HTTPEndpoint() + "/flashbots"
+
+	client, err := rpc.DialOptions(ctx, endpoint, rpc.WithHeader("Authorization", "Bearer "+token))
+	require.NoError(t, err)
+	defer client.Close()
+	require.NoError(t, client.CallContext(ctx, nil, "flashbots_validateBuilderSubmissionV1", blockRequest))
+
+	// Without the bearer token, the request must be rejected by the auth
+	// middleware before it ever reaches BlockValidationAPI.
+	unauth, err := rpc.DialHTTP(endpoint)
+	require.NoError(t, err)
+	defer unauth.Close()
+
+	err = unauth.CallContext(ctx, nil, "flashbots_validateBuilderSubmissionV1", blockRequest)
+	require.Error(t, err)
+
+	var httpErr rpc.HTTPError
+	if ok := require.ErrorAs(t, err, &httpErr); ok {
+		require.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+	}
+}
+
+func TestRegisterValidateBuilderSubmissionV1OverWS(t *testing.T) {
+	genesis, preMergeBlocks := generatePreMergeChain(20)
+	n, ethservice := newEthServiceHTTP(t, genesis)
+	defer n.Close()
+
+	const token = "test-token"
+	require.NoError(t, Register(n, ethservice, BlockValidationConfig{AuthBearerToken: token}))
+
+	startAndImportChain(t, n, ethservice, preMergeBlocks)
+	ethservice.Merger().ReachTTD()
+
+	parent := preMergeBlocks[len(preMergeBlocks)-1]
+	api := NewBlockValidationAPI(ethservice, nil, BlockValidationConfig{})
+	execData, err := assembleBlock(api, parent.Hash(), &beacon.PayloadAttributesV1{
+		Timestamp: parent.Time() + 5,
+	})
+	require.NoError(t, err)
+
+	payload, err := ExecutableDataToExecutionPayload(execData)
+	require.NoError(t, err)
+
+	blockRequest := &boostTypes.BuilderSubmitBlockRequest{
+		Signature: boostTypes.Signature{},
+		Message: &boostTypes.BidTrace{
+			ParentHash: boostTypes.Hash(execData.ParentHash),
+			BlockHash:  boostTypes.Hash(execData.BlockHash),
+			GasLimit:   execData.GasLimit,
+			GasUsed:    execData.GasUsed,
+			Value:      boostTypes.IntToU256(0),
+		},
+		ExecutionPayload: payload,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	endpoint := "ws://" + strings.TrimPrefix(n.HTTPEndpoint(), "http://") + "/flashbots/ws"
+
+	client, err := rpc.DialOptions(ctx, endpoint, rpc.WithHeader("Authorization", "Bearer "+token))
+	require.NoError(t, err)
+	defer client.Close()
+	require.NoError(t, client.CallContext(ctx, nil, "flashbots_validateBuilderSubmissionV1", blockRequest))
+
+	// Without the bearer token, the WS upgrade itself must be rejected by
+	// the auth middleware before it ever reaches the RPC server.
+	_, err = rpc.DialWebsocket(ctx, endpoint, "")
+	require.Error(t, err)
+}
+
+func TestRegisterAuthAllowedIPs(t *testing.T) {
+	genesis, preMergeBlocks := generatePreMergeChain(20)
+	n, ethservice := newEthServiceHTTP(t, genesis)
+	defer n.Close()
+
+	// The test client always connects from 127.0.0.1, so an allowlist that
+	// doesn't include it must forbid every request regardless of the
+	// bearer token.
+	require.NoError(t, Register(n, ethservice, BlockValidationConfig{AuthAllowedIPs: []string{"10.0.0.1"}}))
+
+	startAndImportChain(t, n, ethservice, preMergeBlocks)
+	ethservice.Merger().ReachTTD()
+
+	parent := preMergeBlocks[len(preMergeBlocks)-1]
+	api := NewBlockValidationAPI(ethservice, nil, BlockValidationConfig{})
+	execData, err := assembleBlock(api, parent.Hash(), &beacon.PayloadAttributesV1{
+		Timestamp: parent.Time() + 5,
+	})
+	require.NoError(t, err)
+
+	payload, err := ExecutableDataToExecutionPayload(execData)
+	require.NoError(t, err)
+
+	blockRequest := &boostTypes.BuilderSubmitBlockRequest{
+		Signature: boostTypes.Signature{},
+		Message: &boostTypes.BidTrace{
+			ParentHash: boostTypes.Hash(execData.ParentHash),
+			BlockHash:  boostTypes.Hash(execData.BlockHash),
+			GasLimit:   execData.GasLimit,
+			GasUsed:    execData.GasUsed,
+			Value:      boostTypes.IntToU256(0),
+		},
+		ExecutionPayload: payload,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	endpoint := n.HTTPEndpoint() + "/flashbots"
+
+	client, err := rpc.DialHTTP(endpoint)
+	require.NoError(t, err)
+	defer client.Close()
+
+	err = client.CallContext(ctx, nil, "flashbots_validateBuilderSubmissionV1", blockRequest)
+	require.Error(t, err)
+
+	var httpErr rpc.HTTPError
+	if ok := require.ErrorAs(t, err, &httpErr); ok {
+		require.Equal(t, http.StatusForbidden, httpErr.StatusCode)
+	}
+}